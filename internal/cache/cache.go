@@ -0,0 +1,174 @@
+// internal/cache/cache.go
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	filesBucket = []byte("files")
+	dirsBucket  = []byte("dirs")
+)
+
+// Entry is a previously observed file's identity and metadata, keyed by
+// (Device, Inode) so a rename doesn't invalidate the cache entry.
+type Entry struct {
+	Path   string
+	Size   int64
+	Mtime  time.Time
+	Device uint64
+	Inode  uint64
+
+	// BlockBytes mirrors scan.FileInfo.BlockBytes (the on-disk byte count),
+	// nil for backends that don't report one. Carried through so a
+	// directory cache hit replays the same on-disk/apparent size a fresh
+	// stat would have reported, instead of silently falling back to
+	// apparent size.
+	BlockBytes *int64
+}
+
+// DirEntry is a previously observed directory, along with the file
+// entries it contained and the subdirectories it held at the time it was
+// last scanned. On a later scan, if the directory's Mtime hasn't changed,
+// Children is replayed instead of re-reading and re-stating every file in
+// it, and each of Dirs is checked (and, recursively, replayed) in turn so
+// an unchanged subtree can be skipped all the way down.
+type DirEntry struct {
+	Path     string
+	Mtime    time.Time
+	Device   uint64
+	Inode    uint64
+	Children []Entry
+	Dirs     []DirRef
+}
+
+// DirRef identifies one of a DirEntry's immediate subdirectories, enough
+// to look its own cached DirEntry back up (or re-Stat it to notice it
+// changed) without re-reading the parent directory.
+type DirRef struct {
+	Path   string
+	Device uint64
+	Inode  uint64
+	Mtime  time.Time
+}
+
+// Store is a single BoltDB file holding one scan's worth of Entry and
+// DirEntry records.
+type Store struct {
+	db *bolt.DB
+}
+
+// DefaultPath returns $XDG_CACHE_HOME/lbfq/cache.db, falling back to
+// ~/.cache/lbfq/cache.db when XDG_CACHE_HOME is unset, matching the XDG
+// base-directory convention.
+func DefaultPath() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cache: resolve home directory: %w", err)
+		}
+		base = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(base, "lbfq", "cache.db"), nil
+}
+
+// Open creates (if needed) and opens the BoltDB file at path.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("cache: create cache dir: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cache: open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(filesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(dirsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cache: init buckets: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying BoltDB file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// FileKey is the (device, inode) key files and directories are stored
+// under.
+func FileKey(device, inode uint64) string {
+	return fmt.Sprintf("%d:%d", device, inode)
+}
+
+// PutFile stores (or overwrites) the entry for (entry.Device, entry.Inode).
+func (s *Store) PutFile(entry Entry) error {
+	v, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(filesBucket).Put([]byte(FileKey(entry.Device, entry.Inode)), v)
+	})
+}
+
+// AllFiles returns every file entry currently in the store, for `lbfq
+// diff` to compare against a fresh scan.
+func (s *Store) AllFiles() ([]Entry, error) {
+	var entries []Entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(filesBucket).ForEach(func(_, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// LookupDir returns the cached directory entry for (device, inode), if
+// any.
+func (s *Store) LookupDir(device, inode uint64) (DirEntry, bool, error) {
+	var entry DirEntry
+	found := false
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(dirsBucket).Get([]byte(FileKey(device, inode)))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &entry)
+	})
+	return entry, found, err
+}
+
+// PutDir stores (or overwrites) the directory entry for (entry.Device,
+// entry.Inode).
+func (s *Store) PutDir(entry DirEntry) error {
+	v, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(dirsBucket).Put([]byte(FileKey(entry.Device, entry.Inode)), v)
+	})
+}
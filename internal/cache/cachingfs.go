@@ -0,0 +1,196 @@
+// internal/cache/cachingfs.go
+package cache
+
+import (
+	"path/filepath"
+
+	"lbfq/internal/scan"
+)
+
+// CachingFS wraps another scan.FS and, for a directory whose recorded
+// mtime hasn't changed since it was last scanned, replays its cached
+// entries instead of letting Inner re-read and re-stat them. A hit
+// recurses into the directory's cached subdirectories too (each checked
+// independently via a single Stat, not a full re-read), so an unchanged
+// subtree is skipped all the way down rather than just one level.
+//
+// This assumes a directory's mtime only changes when an entry is added,
+// removed, or renamed within it — true on POSIX filesystems, but it means
+// an in-place write to an existing file (which bumps that file's own
+// mtime, not its parent directory's) won't be noticed until Refresh is
+// set. That tradeoff is acceptable for a nightly capacity-trend scan; it
+// is not a guarantee of exact up-to-date sizes.
+type CachingFS struct {
+	Inner scan.FS
+	Store *Store
+
+	// Refresh forces every directory to be treated as a cache miss (and
+	// its entry refreshed), ignoring any recorded mtime match.
+	Refresh bool
+}
+
+// Walk implements scan.FS.
+func (c CachingFS) Walk(root string, fn scan.WalkFunc, onErr scan.ErrFunc) error {
+	childrenOf := map[string][]scan.FileInfo{}
+	dirsOf := map[string][]scan.FileInfo{}
+	dirsToRefresh := map[string]scan.FileInfo{}
+
+	err := c.Inner.Walk(root, func(info scan.FileInfo) error {
+		if info.IsDir {
+			if parent := filepath.Dir(info.Path); parent != "" {
+				dirsOf[parent] = append(dirsOf[parent], info)
+			}
+
+			if !c.Refresh {
+				if cached, ok := c.lookupDir(info); ok {
+					if err := c.replaySubtree(info, cached, fn, onErr); err != nil {
+						return err
+					}
+					return filepath.SkipDir
+				}
+			}
+
+			dirsToRefresh[info.Path] = info
+			return fn(info)
+		}
+
+		if parent := filepath.Dir(info.Path); parent != "" {
+			childrenOf[parent] = append(childrenOf[parent], info)
+		}
+		return fn(info)
+	}, onErr)
+
+	c.refresh(dirsToRefresh, childrenOf, dirsOf)
+	return err
+}
+
+// Stat implements scan.FS by delegating to Inner; a single Stat call has
+// no cached subtree to replay.
+func (c CachingFS) Stat(path string) (scan.FileInfo, error) {
+	return c.Inner.Stat(path)
+}
+
+// replaySubtree emits info and cached's recorded file children, then
+// walks cached's recorded subdirectories one at a time: a subdirectory
+// whose own mtime still matches is replayed recursively from the cache,
+// and one that has changed falls back to a live (and itself
+// cache-aware) Walk of just that subtree, so only the part that
+// actually changed pays for a real re-read. If fn prunes info with
+// filepath.SkipDir, nothing under it is replayed and its siblings are
+// still visited.
+func (c CachingFS) replaySubtree(info scan.FileInfo, cached DirEntry, fn scan.WalkFunc, onErr scan.ErrFunc) error {
+	if err := fn(info); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+
+	for _, e := range cached.Children {
+		if err := fn(e.toFileInfo()); err != nil {
+			return err
+		}
+	}
+
+	for _, ref := range cached.Dirs {
+		childInfo, err := c.Inner.Stat(ref.Path)
+		if err != nil {
+			onErr(ref.Path, err)
+			continue
+		}
+
+		if subCached, ok := c.lookupDir(childInfo); ok {
+			if err := c.replaySubtree(childInfo, subCached, fn, onErr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := c.Walk(ref.Path, fn, onErr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lookupDir reports whether info's directory has an unchanged cached
+// entry, returning it if so.
+func (c CachingFS) lookupDir(info scan.FileInfo) (DirEntry, bool) {
+	if info.Device == nil || info.Inode == nil {
+		return DirEntry{}, false
+	}
+
+	cached, ok, err := c.Store.LookupDir(*info.Device, *info.Inode)
+	if err != nil || !ok || !cached.Mtime.Equal(info.Mtime) {
+		return DirEntry{}, false
+	}
+
+	return cached, true
+}
+
+// refresh persists every directory visited during Walk (cache misses
+// only; hits are left as-is since they're already accurate) along with
+// the file and subdirectory entries discovered under it.
+func (c CachingFS) refresh(dirs map[string]scan.FileInfo, childrenOf, dirsOf map[string][]scan.FileInfo) {
+	for path, info := range dirs {
+		if info.Device == nil || info.Inode == nil {
+			continue
+		}
+
+		children := make([]Entry, 0, len(childrenOf[path]))
+		for _, child := range childrenOf[path] {
+			e := fileInfoToEntry(child)
+			children = append(children, e)
+			_ = c.Store.PutFile(e)
+		}
+
+		subdirs := make([]DirRef, 0, len(dirsOf[path]))
+		for _, child := range dirsOf[path] {
+			subdirs = append(subdirs, fileInfoToDirRef(child))
+		}
+
+		_ = c.Store.PutDir(DirEntry{
+			Path:     path,
+			Mtime:    info.Mtime,
+			Device:   *info.Device,
+			Inode:    *info.Inode,
+			Children: children,
+			Dirs:     subdirs,
+		})
+	}
+}
+
+func (e Entry) toFileInfo() scan.FileInfo {
+	device, inode := e.Device, e.Inode
+	return scan.FileInfo{
+		Path:       e.Path,
+		Size:       e.Size,
+		Mtime:      e.Mtime,
+		Device:     &device,
+		Inode:      &inode,
+		BlockBytes: e.BlockBytes,
+	}
+}
+
+func fileInfoToEntry(info scan.FileInfo) Entry {
+	e := Entry{Path: info.Path, Size: info.Size, Mtime: info.Mtime, BlockBytes: info.BlockBytes}
+	if info.Device != nil {
+		e.Device = *info.Device
+	}
+	if info.Inode != nil {
+		e.Inode = *info.Inode
+	}
+	return e
+}
+
+func fileInfoToDirRef(info scan.FileInfo) DirRef {
+	ref := DirRef{Path: info.Path, Mtime: info.Mtime}
+	if info.Device != nil {
+		ref.Device = *info.Device
+	}
+	if info.Inode != nil {
+		ref.Inode = *info.Inode
+	}
+	return ref
+}
@@ -0,0 +1,123 @@
+// internal/cache/cachingfs_test.go
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lbfq/internal/cache"
+	"lbfq/internal/scan"
+)
+
+// walkFiles runs fs.Walk(root, ...) and returns every non-directory
+// FileInfo it visits, keyed by path.
+func walkFiles(t *testing.T, fs scan.FS, root string) map[string]scan.FileInfo {
+	t.Helper()
+
+	files := make(map[string]scan.FileInfo)
+	onErr := func(path string, err error) { t.Fatalf("walk %s: %v", path, err) }
+
+	err := fs.Walk(root, func(info scan.FileInfo) error {
+		if !info.IsDir {
+			files[info.Path] = info
+		}
+		return nil
+	}, onErr)
+	if err != nil {
+		t.Fatalf("Walk(%s): %v", root, err)
+	}
+	return files
+}
+
+func TestCachingFS_PopulateThenHitReplaysNestedSubtree(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatalf("write a.txt: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatalf("mkdir sub: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", "big.log"), []byte("some log content"), 0o644); err != nil {
+		t.Fatalf("write sub/big.log: %v", err)
+	}
+
+	store, err := cache.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("cache.Open: %v", err)
+	}
+	defer store.Close()
+
+	fs := cache.CachingFS{Inner: scan.OSFS{}, Store: store}
+
+	// Cold scan: populates the cache.
+	cold := walkFiles(t, fs, root)
+	wantPaths := []string{filepath.Join(root, "a.txt"), filepath.Join(root, "sub", "big.log")}
+	for _, p := range wantPaths {
+		if _, ok := cold[p]; !ok {
+			t.Errorf("cold scan missing %s: %+v", p, cold)
+		}
+	}
+
+	// Warm scan: the top directory's mtime is unchanged, so this replays
+	// entirely from the cache. It must still surface the file nested under
+	// sub/, not just a.txt directly under root.
+	warm := walkFiles(t, fs, root)
+	if len(warm) != len(cold) {
+		t.Fatalf("warm scan returned %d files, cold scan returned %d: warm=%+v", len(warm), len(cold), warm)
+	}
+	for p, coldInfo := range cold {
+		warmInfo, ok := warm[p]
+		if !ok {
+			t.Errorf("warm scan (cache hit) dropped %s — nested subtree not replayed", p)
+			continue
+		}
+		if warmInfo.Size != coldInfo.Size {
+			t.Errorf("%s: warm size = %d, cold size = %d", p, warmInfo.Size, coldInfo.Size)
+		}
+		if (warmInfo.BlockBytes == nil) != (coldInfo.BlockBytes == nil) {
+			t.Errorf("%s: warm BlockBytes = %v, cold BlockBytes = %v (cached entry should round-trip on-disk size)", p, warmInfo.BlockBytes, coldInfo.BlockBytes)
+		}
+	}
+}
+
+func TestCachingFS_RefreshDetectsInPlaceGrowth(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "grows.txt")
+	if err := os.WriteFile(target, []byte("short"), 0o644); err != nil {
+		t.Fatalf("write grows.txt: %v", err)
+	}
+
+	store, err := cache.Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("cache.Open: %v", err)
+	}
+	defer store.Close()
+
+	fs := cache.CachingFS{Inner: scan.OSFS{}, Store: store}
+	before := walkFiles(t, fs, root)[target]
+
+	// Grow the file in place: this bumps the file's own mtime but not
+	// root's, so a directory-level cache hit alone wouldn't notice it.
+	if err := os.WriteFile(target, []byte("a much longer replacement body"), 0o644); err != nil {
+		t.Fatalf("rewrite grows.txt: %v", err)
+	}
+	// Some filesystems have coarse mtime resolution; make sure the new
+	// mtime is observably different from what's cached.
+	newTime := time.Now().Add(time.Second)
+	if err := os.Chtimes(target, newTime, newTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	stale := walkFiles(t, fs, root)[target]
+	if stale.Size != before.Size {
+		t.Fatalf("plain cache hit unexpectedly picked up the growth: got size %d, cached size %d", stale.Size, before.Size)
+	}
+
+	refreshed := walkFiles(t, cache.CachingFS{Inner: scan.OSFS{}, Store: store, Refresh: true}, root)[target]
+	wantSize := int64(len("a much longer replacement body"))
+	if refreshed.Size != wantSize {
+		t.Fatalf("Refresh:true scan size = %d, want %d (in-place growth not detected)", refreshed.Size, wantSize)
+	}
+}
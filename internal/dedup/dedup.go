@@ -0,0 +1,189 @@
+// internal/dedup/dedup.go
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"lbfq/internal/scan"
+)
+
+// fingerprintWindow is the number of bytes read from the head and tail of a
+// file when computing its rolling-hash fingerprint.
+const fingerprintWindow = 64 * 1024
+
+// Group is a set of files that share the same size and, after fingerprint
+// and full-hash confirmation, identical content.
+type Group struct {
+	SizeBytes int64
+	GroupID   string
+	Paths     []string
+}
+
+// sizeClass is a same-size bucket of candidate paths handed to a dedup
+// worker.
+type sizeClass struct {
+	size  int64
+	paths []string
+}
+
+// Find partitions scanned results into duplicate groups. It first buckets
+// files by size, then for size-classes with >=2 entries computes a cheap
+// rolling-hash fingerprint over the head and tail of each file, and only
+// promotes fingerprint collisions to a full SHA-256 hash for confirmation.
+// workers bounds the number of size-classes (and so open files) processed
+// concurrently, reusing the same fixed-pool pattern as scan's workers.
+func Find(results []scan.Result, workers int) []Group {
+	if workers <= 0 {
+		workers = 4
+	}
+
+	bySize := make(map[int64][]string)
+	for _, r := range results {
+		bySize[r.Size] = append(bySize[r.Size], r.Path)
+	}
+
+	classes := make(chan sizeClass)
+	go func() {
+		defer close(classes)
+		for size, paths := range bySize {
+			if len(paths) < 2 {
+				continue
+			}
+			classes <- sizeClass{size: size, paths: paths}
+		}
+	}()
+
+	var mu sync.Mutex
+	var groups []Group
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range classes {
+				found := groupBySize(c.size, c.paths)
+
+				mu.Lock()
+				groups = append(groups, found...)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(groups, func(i, j int) bool {
+		return wastedBytes(groups[i]) > wastedBytes(groups[j])
+	})
+
+	return groups
+}
+
+// wastedBytes is the space reclaimed by keeping a single copy of a group.
+func wastedBytes(g Group) int64 {
+	if len(g.Paths) == 0 {
+		return 0
+	}
+	return g.SizeBytes * int64(len(g.Paths)-1)
+}
+
+// groupBySize narrows a same-size bucket down to confirmed duplicate groups
+// via fingerprint, then full-hash confirmation.
+func groupBySize(size int64, paths []string) []Group {
+	byFingerprint := make(map[uint64][]string)
+	for _, p := range paths {
+		fp, err := fingerprintOf(p, size)
+		if err != nil {
+			// NOTE: unreadable file, skip it rather than fail the whole scan
+			continue
+		}
+		byFingerprint[fp] = append(byFingerprint[fp], p)
+	}
+
+	var groups []Group
+	for _, candidates := range byFingerprint {
+		if len(candidates) < 2 {
+			continue
+		}
+		groups = append(groups, confirmGroups(size, candidates)...)
+	}
+	return groups
+}
+
+// confirmGroups promotes fingerprint collisions to full-content equality via
+// SHA-256, since a fingerprint over only the head and tail can't rule out a
+// false positive on its own.
+func confirmGroups(size int64, paths []string) []Group {
+	byHash := make(map[string][]string)
+	for _, p := range paths {
+		h, err := fullHashOf(p)
+		if err != nil {
+			continue
+		}
+		byHash[h] = append(byHash[h], p)
+	}
+
+	var groups []Group
+	for h, members := range byHash {
+		if len(members) < 2 {
+			continue
+		}
+		groups = append(groups, Group{SizeBytes: size, GroupID: h, Paths: members})
+	}
+	return groups
+}
+
+// fingerprintOf computes a rollsum-style 64-bit checksum over the first and
+// last fingerprintWindow bytes of the file (or the whole file if smaller).
+func fingerprintOf(path string, size int64) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	head := make([]byte, minInt64(fingerprintWindow, size))
+	if _, err := io.ReadFull(f, head); err != nil && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+
+	var tail []byte
+	if size > fingerprintWindow {
+		tail = make([]byte, fingerprintWindow)
+		if _, err := f.Seek(-int64(len(tail)), io.SeekEnd); err != nil {
+			return 0, err
+		}
+		if _, err := io.ReadFull(f, tail); err != nil {
+			return 0, err
+		}
+	}
+
+	return rollsum(head) ^ rollsum(tail)<<1, nil
+}
+
+// fullHashOf hashes the entire file content for collision confirmation.
+func fullHashOf(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
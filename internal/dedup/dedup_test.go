@@ -0,0 +1,127 @@
+// internal/dedup/dedup_test.go
+package dedup
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"lbfq/internal/scan"
+)
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name string, content []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, content, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return p
+	}
+
+	a1 := write("a1.txt", []byte("hello"))
+	a2 := write("a2.txt", []byte("hello"))
+	a3 := write("a3.txt", []byte("world")) // same size as a1/a2, different content
+
+	b1 := write("b1.txt", []byte("hello"))
+	b2 := write("b2.txt", []byte("hello!")) // different size, never compared to b1
+
+	solo := write("solo.txt", []byte("unique")) // same size as b2, different content
+
+	results := []scan.Result{
+		{Size: 5, Path: a1},
+		{Size: 5, Path: a2},
+		{Size: 5, Path: a3},
+		{Size: 5, Path: b1},
+		{Size: 6, Path: b2},
+		{Size: 6, Path: solo},
+	}
+
+	got := Find(results, 2)
+
+	if len(got) != 1 {
+		t.Fatalf("Find() returned %d groups, want 1: %+v", len(got), got)
+	}
+
+	g := got[0]
+	if g.SizeBytes != 5 {
+		t.Errorf("group SizeBytes = %d, want 5", g.SizeBytes)
+	}
+
+	wantPaths := []string{a1, a2, b1}
+	gotPaths := append([]string(nil), g.Paths...)
+	sort.Strings(gotPaths)
+	sort.Strings(wantPaths)
+	if !equalStrings(gotPaths, wantPaths) {
+		t.Errorf("group Paths = %v, want %v", gotPaths, wantPaths)
+	}
+}
+
+func TestFind_SkipsUnreadableFile(t *testing.T) {
+	dir := t.TempDir()
+
+	real := filepath.Join(dir, "real.txt")
+	if err := os.WriteFile(real, []byte("content"), 0o644); err != nil {
+		t.Fatalf("write real.txt: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.txt")
+
+	results := []scan.Result{
+		{Size: 7, Path: real},
+		{Size: 7, Path: missing},
+	}
+
+	got := Find(results, 2)
+	if len(got) != 0 {
+		t.Fatalf("Find() with an unreadable peer returned %d groups, want 0: %+v", len(got), got)
+	}
+}
+
+func TestFind_SortedByWastedBytesDescending(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name string, content []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, content, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return p
+	}
+
+	// Small group: 2 copies of a 1-byte file -> 1 byte wasted.
+	s1 := write("s1.txt", []byte("a"))
+	s2 := write("s2.txt", []byte("a"))
+
+	// Big group: 3 copies of a 10-byte file -> 20 bytes wasted.
+	big := []byte("0123456789")
+	l1 := write("l1.txt", big)
+	l2 := write("l2.txt", big)
+	l3 := write("l3.txt", big)
+
+	results := []scan.Result{
+		{Size: 1, Path: s1}, {Size: 1, Path: s2},
+		{Size: 10, Path: l1}, {Size: 10, Path: l2}, {Size: 10, Path: l3},
+	}
+
+	got := Find(results, 2)
+	if len(got) != 2 {
+		t.Fatalf("Find() returned %d groups, want 2: %+v", len(got), got)
+	}
+	if got[0].SizeBytes != 10 || got[1].SizeBytes != 1 {
+		t.Errorf("groups not sorted by wasted bytes descending: %+v", got)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+// internal/dedup/rollsum.go
+package dedup
+
+// rollWindowSize is the size of the circular window rollsum maintains while
+// scanning buf, bounding how much weight any single byte carries in the
+// final sum.
+const rollWindowSize = 64
+
+// rollsum computes a cheap 64-bit checksum over buf by sliding a small
+// circular window across it once. It's a fixed head/tail fingerprint, not a
+// boundary-detecting content-defined-chunking hash: fingerprintOf calls it
+// exactly once over a file's head and once over its tail, as a fast filter
+// before SHA-256 confirms or refutes a same-size collision.
+func rollsum(buf []byte) uint64 {
+	if len(buf) == 0 {
+		return 0
+	}
+
+	var window [rollWindowSize]byte
+	var a, b uint32
+
+	for i, c := range buf {
+		idx := i % rollWindowSize
+		out := window[idx]
+		window[idx] = c
+
+		a = a - uint32(out) + uint32(c)
+		b = b - uint32(rollWindowSize)*uint32(out) + a
+	}
+
+	return uint64(a) | uint64(b)<<32
+}
@@ -0,0 +1,78 @@
+// internal/scan/fs.go
+package scan
+
+import "time"
+
+// FileInfo is the subset of file metadata the walker and scorer need,
+// independent of which backend produced it.
+type FileInfo struct {
+	Path string
+	Size int64 // apparent size, in bytes
+
+	// BlockBytes is the on-disk byte count (e.g. POSIX st_blocks*512) when
+	// the backend can report one. Backends without a notion of on-disk
+	// size (S3, tar, zip) leave this nil and Size is used regardless of
+	// Config.Apparent.
+	BlockBytes *int64
+
+	// Device identifies the filesystem/volume a path lives on, for XDev
+	// pruning. Backends without that concept (S3, tar, zip) leave this nil,
+	// which disables XDev pruning on that backend.
+	Device *uint64
+
+	// Inode, together with Device, identifies a file independent of its
+	// path, used by internal/cache to recognize an unmodified file across
+	// scans even if it was renamed. Backends without a stable inode leave
+	// this nil.
+	Inode *uint64
+
+	// Mtime and Ctime are the backend's notion of content-modified and
+	// metadata-changed time, used by internal/cache to detect unmodified
+	// files and directories between scans. Left zero for backends with no
+	// such concept.
+	Mtime time.Time
+	Ctime time.Time
+
+	IsDir   bool
+	Symlink bool
+}
+
+// WalkFunc is called once per entry a FS backend discovers. Returning
+// filepath.SkipDir from a directory entry prunes that subtree; any other
+// non-nil error aborts the walk and is returned from Walk.
+type WalkFunc func(info FileInfo) error
+
+// ErrFunc is called for a path the backend couldn't read or stat (e.g.
+// permission denied), instead of the backend silently skipping it. The
+// walk continues afterward.
+type ErrFunc func(path string, err error)
+
+// FS abstracts the backend a scan walks against, so the same walker and
+// worker pool can target local disks, object stores, and archives alike.
+// Backends differ in what "on-disk size", "symlink", and "device" even
+// mean:
+//
+//   - OSFS reports true on-disk block usage (when Config.Apparent is false)
+//     and follows local-filesystem symlink and XDev semantics.
+//   - S3FS has no notion of on-disk size, symlinks, or a device: Size is
+//     always the object's apparent content-length, BlockBytes/Device are
+//     nil, and no per-object Stat is needed to list a bucket.
+//   - TarFS/ZipFS report the apparent size recorded in the archive header,
+//     synthesize directories from path prefixes, and never report symlinks
+//     or a device (an archive isn't split across filesystems).
+//   - SFTPFS mirrors OSFS's symlink semantics as reported by the remote
+//     server, but reports only the apparent size (BlockBytes is nil);
+//     Device is left nil since SFTP has no stable device identifier
+//     across servers.
+type FS interface {
+	// Walk calls fn once for every entry reachable from root, depth-first,
+	// and onErr for every entry the backend couldn't read. Implementations
+	// should stop early and propagate fn's error, except for
+	// filepath.SkipDir on a directory entry, which prunes that subtree and
+	// continues the walk.
+	Walk(root string, fn WalkFunc, onErr ErrFunc) error
+
+	// Stat returns metadata for a single path, used to resolve cfg.Root
+	// itself (e.g. for XDev's root device) without a full walk.
+	Stat(path string) (FileInfo, error)
+}
@@ -0,0 +1,72 @@
+// internal/scan/osfs.go
+package scan
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// OSFS walks the local filesystem. It's the default backend and preserves
+// the on-disk/apparent size and symlink semantics lbfq has always had.
+type OSFS struct{}
+
+// Walk implements FS by delegating to filepath.WalkDir. Unreadable entries
+// (permission denied, races with deletion) are reported to onErr and
+// skipped rather than aborting the whole walk.
+func (OSFS) Walk(root string, fn WalkFunc, onErr ErrFunc) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			onErr(path, err)
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			onErr(path, err)
+			return nil
+		}
+
+		return fn(toFileInfo(path, info))
+	})
+}
+
+// Stat implements FS for a single local path.
+func (OSFS) Stat(path string) (FileInfo, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	return toFileInfo(path, info), nil
+}
+
+// toFileInfo translates an os.FileInfo into the backend-neutral FileInfo,
+// filling in the on-disk block count, device, inode, and timestamps so
+// OSFS can support Config.Apparent, Config.XDev, and internal/cache.
+func toFileInfo(path string, info fs.FileInfo) FileInfo {
+	fi := FileInfo{
+		Path:    path,
+		Size:    info.Size(),
+		Mtime:   info.ModTime(),
+		IsDir:   info.IsDir(),
+		Symlink: info.Mode()&os.ModeSymlink != 0,
+	}
+
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		// NOTE: POSIX's st_blocks is in 512-byte units -> on-disk bytes
+		blocks := st.Blocks * 512
+		fi.BlockBytes = &blocks
+
+		dev := uint64(st.Dev)
+		fi.Device = &dev
+
+		inode := uint64(st.Ino)
+		fi.Inode = &inode
+
+		fi.Ctime = time.Unix(st.Ctim.Sec, st.Ctim.Nsec)
+	}
+
+	return fi
+}
@@ -0,0 +1,73 @@
+// internal/scan/progress.go
+package scan
+
+import (
+	"fmt"
+	"sync"
+)
+
+// maxProgressErrors bounds how many individual error strings Progress
+// carries, so a scan hitting millions of permission denials doesn't blow
+// up memory; ErrorCount still reflects the true total.
+const maxProgressErrors = 50
+
+// Progress is a point-in-time snapshot of an in-flight Scan, delivered
+// periodically on the channel returned alongside Result.
+type Progress struct {
+	FilesScanned int64
+	BytesSeen    int64
+	CurrentDir   string
+	Errors       []string // capped at maxProgressErrors
+	ErrorCount   int64    // true total, independent of the Errors cap
+}
+
+// progressState accumulates counters safe for concurrent use by the
+// walker goroutine and every worker, snapshotted into a Progress value on
+// demand.
+type progressState struct {
+	mu           sync.Mutex
+	filesScanned int64
+	bytesSeen    int64
+	currentDir   string
+	errs         []string
+	errCount     int64
+}
+
+func (p *progressState) addScanned(size int64) {
+	p.mu.Lock()
+	p.filesScanned++
+	p.bytesSeen += size
+	p.mu.Unlock()
+}
+
+func (p *progressState) setCurrentDir(dir string) {
+	p.mu.Lock()
+	p.currentDir = dir
+	p.mu.Unlock()
+}
+
+func (p *progressState) addError(path string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.errCount++
+	if len(p.errs) < maxProgressErrors {
+		p.errs = append(p.errs, fmt.Sprintf("%s: %v", path, err))
+	}
+}
+
+func (p *progressState) snapshot() Progress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	errs := make([]string, len(p.errs))
+	copy(errs, p.errs)
+
+	return Progress{
+		FilesScanned: p.filesScanned,
+		BytesSeen:    p.bytesSeen,
+		CurrentDir:   p.currentDir,
+		Errors:       errs,
+		ErrorCount:   p.errCount,
+	}
+}
@@ -0,0 +1,119 @@
+// internal/scan/rootfs.go
+package scan
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// OpenFS dispatches cfg.Root's URI scheme to the matching backend and
+// returns the backend plus the root path to hand to FS.Walk (the scheme
+// and host stripped off, where applicable).
+//
+// insecureHostKey, when true, skips SSH host-key verification for
+// sftp:// roots instead of checking against ~/.ssh/known_hosts; it's
+// ignored for every other scheme.
+//
+// Supported prefixes:
+//
+//	s3://bucket/prefix   -> S3FS
+//	sftp://[user@]host/path -> SFTPFS
+//	tar:///abs/path.tar  -> TarFS
+//	zip:///abs/path.zip  -> ZipFS
+//	(no recognized scheme) -> OSFS, root used as-is
+func OpenFS(root string, insecureHostKey bool) (FS, string, error) {
+	switch {
+	case strings.HasPrefix(root, "s3://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(root, "s3://"), "/")
+		fsImpl, err := NewS3FS(context.Background(), bucket, prefix)
+		if err != nil {
+			return nil, "", err
+		}
+		return fsImpl, prefix, nil
+
+	case strings.HasPrefix(root, "sftp://"):
+		return openSFTPFS(strings.TrimPrefix(root, "sftp://"), insecureHostKey)
+
+	case strings.HasPrefix(root, "tar://"):
+		archivePath := strings.TrimPrefix(root, "tar://")
+		return TarFS{ArchivePath: archivePath}, archivePath, nil
+
+	case strings.HasPrefix(root, "zip://"):
+		archivePath := strings.TrimPrefix(root, "zip://")
+		return ZipFS{ArchivePath: archivePath}, archivePath, nil
+
+	default:
+		return OSFS{}, root, nil
+	}
+}
+
+// openSFTPFS parses a "[user@]host[:port]/path" remainder and dials out,
+// authenticating via the running SSH agent (as most sftp-backed CLI tools
+// do rather than prompting for a password).
+func openSFTPFS(remainder string, insecureHostKey bool) (FS, string, error) {
+	hostPart, path, ok := strings.Cut(remainder, "/")
+	if !ok {
+		return nil, "", fmt.Errorf("sftpfs: root %q is missing a path after the host", remainder)
+	}
+	path = "/" + path
+
+	user := "root"
+	host := hostPart
+	if at := strings.IndexByte(hostPart, '@'); at >= 0 {
+		user, host = hostPart[:at], hostPart[at+1:]
+	}
+	if !strings.Contains(host, ":") {
+		host += ":22"
+	}
+
+	sock, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
+	if err != nil {
+		return nil, "", fmt.Errorf("sftpfs: connect to ssh-agent: %w", err)
+	}
+	agentClient := agent.NewClient(sock)
+
+	hostKeyCallback, err := sftpHostKeyCallback(insecureHostKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sshCfg := &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeysCallback(agentClient.Signers)},
+		HostKeyCallback: hostKeyCallback,
+	}
+
+	fsImpl, err := NewSFTPFS(host, sshCfg)
+	if err != nil {
+		return nil, "", err
+	}
+	return fsImpl, path, nil
+}
+
+// sftpHostKeyCallback returns a callback that verifies the remote host key
+// against the user's ~/.ssh/known_hosts, or (only when the caller opted in
+// via insecure) one that accepts any host key unverified.
+func sftpHostKeyCallback(insecure bool) (ssh.HostKeyCallback, error) {
+	if insecure {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("sftpfs: resolve home directory for known_hosts: %w", err)
+	}
+
+	cb, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, fmt.Errorf("sftpfs: load known_hosts (pass -sftp-insecure-host-key to skip verification): %w", err)
+	}
+	return cb, nil
+}
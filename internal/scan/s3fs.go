@@ -0,0 +1,82 @@
+// internal/scan/s3fs.go
+package scan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3FS walks the objects under a bucket/prefix via ListObjectsV2. Object
+// size comes straight from the listing page, so no per-object HeadObject
+// ("Stat") call is needed during a walk.
+type S3FS struct {
+	Bucket string
+	Prefix string
+
+	client *s3.Client
+}
+
+// NewS3FS builds an S3FS backend using the default AWS credential chain
+// (env vars, shared config, instance role, ...).
+func NewS3FS(ctx context.Context, bucket, prefix string) (*S3FS, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s3fs: load AWS config: %w", err)
+	}
+	return &S3FS{Bucket: bucket, Prefix: prefix, client: s3.NewFromConfig(cfg)}, nil
+}
+
+// Walk pages through ListObjectsV2 under root (an "s3://bucket/" relative
+// key prefix). S3 has no directories, symlinks, or device: every key is
+// reported as a plain file at its apparent Content-Length. A failed page
+// fetch is reported to onErr and ends the walk, since S3 gives no way to
+// skip past a bad page and keep listing.
+func (s *S3FS) Walk(root string, fn WalkFunc, onErr ErrFunc) error {
+	ctx := context.Background()
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(root),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			onErr(root, err)
+			return fmt.Errorf("s3fs: list objects: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			size := int64(0)
+			if obj.Size != nil {
+				size = *obj.Size
+			}
+
+			if err := fn(FileInfo{Path: "s3://" + s.Bucket + "/" + aws.ToString(obj.Key), Size: size}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stat issues a single HeadObject for path (an object key) since a listing
+// isn't available outside of Walk.
+func (s *S3FS) Stat(key string) (FileInfo, error) {
+	out, err := s.client.HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("s3fs: head object %q: %w", key, err)
+	}
+
+	size := int64(0)
+	if out.ContentLength != nil {
+		size = *out.ContentLength
+	}
+	return FileInfo{Path: "s3://" + s.Bucket + "/" + key, Size: size}, nil
+}
@@ -2,13 +2,11 @@
 package scan
 
 import (
-	"io/fs"
-	"os"
+	"context"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"sync"
-	"syscall"
+	"time"
 )
 
 type Result struct {
@@ -21,112 +19,189 @@ type Config struct {
 	MinSize      int64
 	XDev         bool
 	Apparent     bool
+	Dedup        bool     // caller wants duplicate-groups instead of a flat listing
+	MaxDepth     int      // 0 means unlimited; depth of Root itself is 0
 	Workers      int      // 0 means auto-tune based on CPU cores
 	Skips        []string // hard prefixes to skip (e.g. /proc)
 	ExcludeGlobs []string // user globs matched on full path (e.g. *.log)
+
+	// SFTPInsecureHostKey skips SSH host-key verification for sftp://
+	// roots instead of checking against ~/.ssh/known_hosts. Only consulted
+	// when Scan resolves Root itself (FS is nil); ignored otherwise.
+	SFTPInsecureHostKey bool
+
+	// FS and WalkRoot let a caller supply an already-resolved backend (e.g.
+	// internal/cache's CachingFS wrapping the result of OpenFS) instead of
+	// having Scan resolve Root itself. Both must be set together; if FS is
+	// nil, Scan resolves Root via OpenFS as usual.
+	FS       FS
+	WalkRoot string
 }
 
-// Scan walks the tree and streams file results >= MinSize into the returned channel.
-// The channel closes when scanning completes.
-func Scan(cfg Config) <-chan Result {
+// progressInterval is how often a Progress snapshot is pushed while a scan
+// is in flight.
+const progressInterval = 250 * time.Millisecond
+
+// Scan walks the tree and streams file results >= MinSize into the first
+// returned channel, alongside periodic Progress snapshots on the second.
+// Both channels close once scanning completes or ctx is cancelled. A caller
+// that stops draining Progress does not stall results: progress ticks it
+// hasn't read are dropped. Root may be a plain path
+// (walked via OSFS) or a URI with a recognized scheme (s3://, sftp://,
+// tar://, zip://); see OpenFS for the full list.
+func Scan(ctx context.Context, cfg Config) (<-chan Result, <-chan Progress, error) {
 	if cfg.Workers <= 0 {
 		// NOTE: Assume there are enough I/O operations to keep 8 workers busy.
 		cfg.Workers = autoWorkers()
 	}
-	paths := make(chan string, 4096)
-	results := make(chan Result, 4096)
+
+	fsImpl, walkRoot := cfg.FS, cfg.WalkRoot
+	if fsImpl == nil {
+		var err error
+		fsImpl, walkRoot, err = OpenFS(cfg.Root, cfg.SFTPInsecureHostKey)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
 	var rootDev uint64
+	haveRootDev := false
 	if cfg.XDev {
-		if d, err := devOf(cfg.Root); err == nil {
-			rootDev = d
+		if fi, err := fsImpl.Stat(walkRoot); err == nil && fi.Device != nil {
+			rootDev, haveRootDev = *fi.Device, true
 		} else {
-			// leave rootDev=0 and skip xDev checks
+			// leave haveRootDev=false and skip xDev checks
 			cfg.XDev = false
 		}
 	}
 
+	infos := make(chan FileInfo, 4096)
+	results := make(chan Result, 4096)
+	progress := make(chan Progress, 1)
+	state := &progressState{}
+
 	var wg sync.WaitGroup
 
-	// Producer: walk filesystem
+	// Producer: walk the backend
 	wg.Add(1)
-
 	go func() {
 		defer wg.Done()
-		_ = filepath.WalkDir(cfg.Root, func(path string, d fs.DirEntry, err error) error {
-			// NOTE: Skip unreadable directory
-			if err != nil {
-				return nil
+		defer close(infos)
+
+		_ = fsImpl.Walk(walkRoot, func(info FileInfo) error {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
 
 			// NOTE: Prune skipped prefixes and excluded globs
-			if hasPrefix(path, cfg.Skips) {
-				if d.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
+			if hasPrefix(info.Path, cfg.Skips) {
+				return pruneOrSkip(info)
+			}
+			if matchAnyGlob(info.Path, cfg.ExcludeGlobs) {
+				return pruneOrSkip(info)
 			}
 
-			if matchAnyGlob(path, cfg.ExcludeGlobs) {
-				if d.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
+			// NOTE: Bounded-depth walk, relative to walkRoot
+			if cfg.MaxDepth > 0 && depthOf(walkRoot, info.Path) > cfg.MaxDepth {
+				return pruneOrSkip(info)
 			}
 
 			// NOTE: XDev pruning (compare device for dirs and files)
-			if cfg.XDev {
-				var st syscall.Stat_t
-				if err := syscall.Lstat(path, &st); err == nil {
-					if uint64(st.Dev) != rootDev {
-						if d.IsDir() {
-							return filepath.SkipDir
-						}
-						return nil
-					}
-				}
+			if cfg.XDev && haveRootDev && info.Device != nil && *info.Device != rootDev {
+				return pruneOrSkip(info)
 			}
 
 			// NOTE: Ignore symlinks entirely
-			if d.Type()&os.ModeSymlink != 0 {
-				if d.IsDir() {
-					return filepath.SkipDir
-				}
+			if info.Symlink {
+				return pruneOrSkip(info)
+			}
+
+			if info.IsDir {
+				state.setCurrentDir(info.Path)
 				return nil
 			}
 
-			paths <- path
+			select {
+			case infos <- info:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 			return nil
-		})
-		close(paths)
+		}, state.addError)
 	}()
 
-	// Consumers: state files and emit >= MinSize
+	// Consumers: size-filter and emit >= MinSize
 	for i := 0; i < cfg.Workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for path := range paths {
-				info, err := os.Lstat(path)
-				if err != nil || info.IsDir() {
-					continue
-				}
-
+			for info := range infos {
 				sz := fileBytes(info, cfg.Apparent)
+				state.addScanned(sz)
 				if sz >= cfg.MinSize {
-					results <- Result{Size: sz, Path: path}
+					select {
+					case results <- Result{Size: sz, Path: info.Path}:
+					case <-ctx.Done():
+						return
+					}
 				}
 			}
 		}()
 	}
 
-	// Closer
+	done := make(chan struct{})
 	go func() {
 		wg.Wait()
 		close(results)
+		close(done)
+	}()
+
+	// Progress reporter: snapshot on a tick, plus a final snapshot on exit.
+	go func() {
+		defer close(progress)
+
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case progress <- state.snapshot():
+				default:
+					// NOTE: Drop a tick rather than block the walker/workers on a
+					// caller that isn't draining Progress.
+				}
+			case <-done:
+				progress <- state.snapshot()
+				return
+			case <-ctx.Done():
+				progress <- state.snapshot()
+				return
+			}
+		}
 	}()
 
-	return results
+	return results, progress, nil
+}
+
+// pruneOrSkip tells the walker to prune a directory subtree, or skip a
+// single non-directory entry.
+func pruneOrSkip(info FileInfo) error {
+	if info.IsDir {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// depthOf returns how many directories separate path from root (0 if path
+// is root itself).
+func depthOf(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
 }
 
 // Check if path matches any of the given globs
@@ -150,25 +225,6 @@ func matchAnyGlob(path string, globs []string) bool {
 	return false
 }
 
-// Calculate an automatic number of workers based on CPU cores
-func autoWorkers() int {
-	n := runtime.NumCPU()
-	if n < 1 {
-		n = 1
-	}
-
-	// Considering IO-bound, we expect a performance benefit
-	// by oversubscribing workers by a factor of 2.
-	n *= 2
-	if n < 4 {
-		n = 4
-	}
-	if n > 64 {
-		n = 64
-	}
-	return n
-}
-
 // Check if path has any of the given prefixes
 func hasPrefix(path string, prefixes []string) bool {
 	for _, p := range prefixes {
@@ -179,25 +235,11 @@ func hasPrefix(path string, prefixes []string) bool {
 	return false
 }
 
-// Get the size of the file, either apparent or on-disk
-func fileBytes(info fs.FileInfo, apparent bool) int64 {
-	if apparent {
-		return info.Size()
-	}
-	if st, ok := info.Sys().(*syscall.Stat_t); ok {
-		// NOTE:
-		// POSIX's st_blocks is in 512-byte units -> on-disk bytes
-		return st.Blocks * 512
-	}
-	return info.Size()
-}
-
-// Get the device ID of the filesystem containing the path
-// It's for non-crossing device checks
-func devOf(path string) (uint64, error) {
-	var st syscall.Stat_t
-	if err := syscall.Lstat(path, &st); err != nil {
-		return 0, err
+// Get the size of the file, either apparent or on-disk (falling back to
+// apparent size for backends that don't report on-disk block usage).
+func fileBytes(info FileInfo, apparent bool) int64 {
+	if apparent || info.BlockBytes == nil {
+		return info.Size
 	}
-	return uint64(st.Dev), nil
+	return *info.BlockBytes
 }
@@ -0,0 +1,76 @@
+// internal/scan/sftpfs.go
+package scan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTPFS walks a remote directory tree over an established SFTP session.
+// It mirrors OSFS's symlink handling but leaves Device nil: SFTP has no
+// stable cross-server device identifier to compare for XDev pruning.
+type SFTPFS struct {
+	client *sftp.Client
+}
+
+// NewSFTPFS dials host (host:port) over SSH using the given client config
+// and opens an SFTP session on top of it.
+func NewSFTPFS(host string, sshCfg *ssh.ClientConfig) (*SFTPFS, error) {
+	conn, err := ssh.Dial("tcp", host, sshCfg)
+	if err != nil {
+		return nil, fmt.Errorf("sftpfs: dial %s: %w", host, err)
+	}
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("sftpfs: open sftp session: %w", err)
+	}
+
+	return &SFTPFS{client: client}, nil
+}
+
+// Walk uses the sftp client's own Walk, which mirrors filepath.WalkDir's
+// depth-first order over the remote tree.
+func (s *SFTPFS) Walk(root string, fn WalkFunc, onErr ErrFunc) error {
+	walker := s.client.Walk(root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			// NOTE: Report and skip unreadable remote entry rather than aborting
+			onErr(walker.Path(), err)
+			continue
+		}
+
+		if err := fn(toRemoteFileInfo(walker.Path(), walker.Stat())); err != nil {
+			if err == filepath.SkipDir {
+				// NOTE: Prune just this subtree, as filepath.WalkDir does
+				walker.SkipDir()
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Stat implements FS for a single remote path.
+func (s *SFTPFS) Stat(path string) (FileInfo, error) {
+	info, err := s.client.Lstat(path)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("sftpfs: stat %q: %w", path, err)
+	}
+	return toRemoteFileInfo(path, info), nil
+}
+
+func toRemoteFileInfo(path string, info os.FileInfo) FileInfo {
+	return FileInfo{
+		Path:    path,
+		Size:    info.Size(),
+		IsDir:   info.IsDir(),
+		Symlink: info.Mode()&os.ModeSymlink != 0,
+	}
+}
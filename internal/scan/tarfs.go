@@ -0,0 +1,108 @@
+// internal/scan/tarfs.go
+package scan
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// TarFS walks the entries of a single tar archive, reported under
+// root-relative paths prefixed with the archive path itself (e.g.
+// "/tmp/x.tar/etc/passwd"). Directory entries are synthesized from path
+// prefixes for archives that don't store explicit directory headers.
+type TarFS struct {
+	// ArchivePath is the on-disk path to the .tar file this backend reads.
+	// Gzip-compressed archives (.tar.gz) aren't supported: Walk feeds the
+	// file straight to tar.NewReader with no gzip layer.
+	ArchivePath string
+}
+
+// Walk streams entries from the archive in header order. It never reports
+// symlinks or a device: an archive member's "on-disk size" is simply the
+// size recorded in its header. onErr is only invoked if the archive itself
+// is truncated mid-stream; a bad header elsewhere aborts Walk outright.
+func (t TarFS) Walk(root string, fn WalkFunc, onErr ErrFunc) error {
+	f, err := os.Open(t.ArchivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	seenDirs := make(map[string]bool)
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			// NOTE: Truncated archive: report it and keep what was read so far
+			onErr(root, err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entryPath := path.Join(root, hdr.Name)
+		if err := emitParentDirs(entryPath, root, seenDirs, fn); err != nil {
+			return err
+		}
+
+		isDir := hdr.Typeflag == tar.TypeDir
+		if isDir {
+			seenDirs[entryPath] = true
+		}
+
+		if err := fn(FileInfo{Path: entryPath, Size: hdr.Size, IsDir: isDir}); err != nil {
+			return err
+		}
+	}
+}
+
+// Stat reads the archive header-by-header until it finds path. Archives
+// aren't indexed, so this is O(n) in the number of entries.
+func (t TarFS) Stat(p string) (FileInfo, error) {
+	f, err := os.Open(t.ArchivePath)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return FileInfo{}, os.ErrNotExist
+		}
+		if err != nil {
+			return FileInfo{}, err
+		}
+
+		entryPath := path.Join(t.ArchivePath, hdr.Name)
+		if entryPath == p {
+			return FileInfo{Path: entryPath, Size: hdr.Size, IsDir: hdr.Typeflag == tar.TypeDir}, nil
+		}
+	}
+}
+
+// emitParentDirs synthesizes directory FileInfo entries for any ancestor
+// of entryPath (up to root) not already seen, so callers that prune on
+// filepath.SkipDir for a directory still work against archives that omit
+// explicit directory headers.
+func emitParentDirs(entryPath, root string, seenDirs map[string]bool, fn WalkFunc) error {
+	dir := filepath.Dir(entryPath)
+	if dir == root || dir == "." || dir == "/" || seenDirs[dir] {
+		return nil
+	}
+
+	if err := emitParentDirs(dir, root, seenDirs, fn); err != nil {
+		return err
+	}
+
+	seenDirs[dir] = true
+	return fn(FileInfo{Path: dir, IsDir: true})
+}
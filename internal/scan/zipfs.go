@@ -0,0 +1,62 @@
+// internal/scan/zipfs.go
+package scan
+
+import (
+	"archive/zip"
+	"os"
+	"path"
+)
+
+// ZipFS walks the entries of a single zip archive, reported under
+// root-relative paths prefixed with the archive path itself. Like TarFS,
+// Stat re-reads and linearly scans the archive's entries; it's O(n) in
+// entry count, not O(1), despite the central directory being seekable.
+type ZipFS struct {
+	// ArchivePath is the on-disk path to the .zip file this backend reads.
+	ArchivePath string
+}
+
+// Walk iterates the archive's central directory. It never reports
+// symlinks or a device, matching TarFS.
+func (z ZipFS) Walk(root string, fn WalkFunc, onErr ErrFunc) error {
+	r, err := zip.OpenReader(z.ArchivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	seenDirs := make(map[string]bool)
+	for _, entry := range r.File {
+		entryPath := path.Join(root, entry.Name)
+		if err := emitParentDirs(entryPath, root, seenDirs, fn); err != nil {
+			return err
+		}
+
+		isDir := entry.FileInfo().IsDir()
+		if isDir {
+			seenDirs[entryPath] = true
+		}
+
+		if err := fn(FileInfo{Path: entryPath, Size: int64(entry.UncompressedSize64), IsDir: isDir}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stat looks up a single entry by its fully joined path.
+func (z ZipFS) Stat(p string) (FileInfo, error) {
+	r, err := zip.OpenReader(z.ArchivePath)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	defer r.Close()
+
+	for _, entry := range r.File {
+		entryPath := path.Join(z.ArchivePath, entry.Name)
+		if entryPath == p {
+			return FileInfo{Path: entryPath, Size: int64(entry.UncompressedSize64), IsDir: entry.FileInfo().IsDir()}, nil
+		}
+	}
+	return FileInfo{}, os.ErrNotExist
+}
@@ -2,19 +2,29 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
 	"strings"
 
+	"lbfq/internal/cache"
+	"lbfq/internal/dedup"
 	"lbfq/internal/scan"
 	"lbfq/internal/topn"
 	"lbfq/internal/units"
 )
 
 func main() {
-	root := flag.String("root", "/", "directory to scan")
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		runDiff(os.Args[2:])
+		return
+	}
+
+	root := flag.String("root", "/", "directory to scan, or a s3://, sftp://, tar://, zip:// URI")
 	topN := flag.Int("n", 50, "show top N largest files")
 	minStr := flag.String("min", "0", "only list files >= size (e.g. 100M, 1G)")
 	xdev := flag.Bool("xdev", true, "stay on the same filesystem")
@@ -22,6 +32,12 @@ func main() {
 	workers := flag.Int("workers", 0, "concurrent stat workers")
 	ndjson := flag.Bool("ndjson", false, "print results as newline-delimited JSON(NDJSON) format")
 	excludeGlobs := flag.String("exclude-globs", "", "comma-separated list of glob patterns to exclude from scan")
+	dupes := flag.Bool("dupes", false, "find duplicate files by content instead of listing top-N largest")
+	maxDepth := flag.Int("max-depth", 0, "maximum directory depth to descend from -root (0 = unlimited)")
+	cachePath := flag.String("cache", "", "persistent scan cache file (default: $XDG_CACHE_HOME/lbfq/cache.db)")
+	noCache := flag.Bool("no-cache", false, "disable the persistent scan cache")
+	refresh := flag.Bool("refresh", false, "ignore cached directory mtimes and re-stat everything")
+	sftpInsecureHostKey := flag.Bool("sftp-insecure-host-key", false, "skip SSH host-key verification for sftp:// roots (insecure)")
 	flag.Parse()
 
 	minSize, err := units.ParseSize(*minStr)
@@ -35,18 +51,68 @@ func main() {
 		MinSize:  minSize,
 		XDev:     *xdev,
 		Apparent: *apparent,
+		Dedup:    *dupes,
+		MaxDepth: *maxDepth,
 		// NOTE: 0 workers means auto-tune based on CPU cores
 		Workers: *workers,
 		// NOTE:
 		// Hard-coded skips for common virtual filesystems.
 		// They're usually not interesting for disk usage analysis.
-		Skips:        []string{"/proc", "/sys", "/run", "/dev"},
-		ExcludeGlobs: splitGlobs(*excludeGlobs),
+		Skips:               []string{"/proc", "/sys", "/run", "/dev"},
+		ExcludeGlobs:        splitGlobs(*excludeGlobs),
+		SFTPInsecureHostKey: *sftpInsecureHostKey,
+	}
+
+	if !*noCache {
+		store, err := openCache(*cachePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cache: %v\n", err)
+			os.Exit(1)
+		}
+		defer store.Close()
+
+		fsImpl, walkRoot, err := scan.OpenFS(*root, *sftpInsecureHostKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "scan: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.FS = cache.CachingFS{Inner: fsImpl, Store: store, Refresh: *refresh}
+		cfg.WalkRoot = walkRoot
+	}
+
+	// NOTE: Let an operator Ctrl-C out of a long scan (e.g. of "/") cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results, progress, err := scan.Scan(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan: %v\n", err)
+		os.Exit(1)
+	}
+	defer startProgress(progress)()
+
+	if cfg.Dedup {
+		var collected []scan.Result
+		for r := range results {
+			collected = append(collected, r)
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		type dupeRec struct {
+			SizeBytes int64    `json:"size_bytes"`
+			GroupID   string   `json:"group_id"`
+			Paths     []string `json:"paths"`
+		}
+
+		for _, g := range dedup.Find(collected, cfg.Workers) {
+			_ = enc.Encode(dupeRec{SizeBytes: g.SizeBytes, GroupID: g.GroupID, Paths: g.Paths})
+		}
+		return
 	}
 
 	keeper := topn.NewKeeper(*topN)
 
-	for r := range scan.Scan(cfg) {
+	for r := range results {
 		keeper.Consider(topn.Item{Size: r.Size, Path: r.Path})
 	}
 
@@ -78,6 +144,148 @@ func main() {
 	}
 }
 
+// runDiff implements the "lbfq diff" subcommand: it re-scans -root against
+// the persistent cache and reports the top growers by delta bytes since
+// the cache was last populated.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	root := fs.String("root", "/", "directory to diff against the cache")
+	cachePath := fs.String("cache", "", "persistent scan cache file (default: $XDG_CACHE_HOME/lbfq/cache.db)")
+	topN := fs.Int("n", 20, "show top N growers by delta bytes")
+	workers := fs.Int("workers", 0, "concurrent stat workers")
+	xdev := fs.Bool("xdev", true, "stay on the same filesystem")
+	sftpInsecureHostKey := fs.Bool("sftp-insecure-host-key", false, "skip SSH host-key verification for sftp:// roots (insecure)")
+	_ = fs.Parse(args)
+
+	store, err := openCache(*cachePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	before, err := store.AllFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		os.Exit(1)
+	}
+	beforeByKey := make(map[string]cache.Entry, len(before))
+	for _, e := range before {
+		beforeByKey[cache.FileKey(e.Device, e.Inode)] = e
+	}
+
+	fsImpl, walkRoot, err := scan.OpenFS(*root, *sftpInsecureHostKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg := scan.Config{
+		Root:    *root,
+		XDev:    *xdev,
+		Workers: *workers,
+		// NOTE: Refresh:true because diff's entire job is spotting files that
+		// changed size in place, which bumps a file's own mtime but not its
+		// parent directory's — a directory-level cache hit would mask
+		// exactly the growth/shrinkage diff exists to report.
+		FS:       cache.CachingFS{Inner: fsImpl, Store: store, Refresh: true},
+		WalkRoot: walkRoot,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results, progress, err := scan.Scan(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		os.Exit(1)
+	}
+	defer startProgress(progress)()
+
+	// Drain results; the cache is refreshed as a side effect of the walk.
+	for range results {
+	}
+
+	after, err := store.AllFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		os.Exit(1)
+	}
+
+	type growth struct {
+		Path          string `json:"path"`
+		PrevSizeBytes int64  `json:"prev_size_bytes"`
+		SizeBytes     int64  `json:"size_bytes"`
+		DeltaBytes    int64  `json:"delta_bytes"`
+	}
+
+	var grown []growth
+	for _, e := range after {
+		prev, ok := beforeByKey[cache.FileKey(e.Device, e.Inode)]
+		if !ok || e.Size <= prev.Size {
+			continue
+		}
+		grown = append(grown, growth{Path: e.Path, PrevSizeBytes: prev.Size, SizeBytes: e.Size, DeltaBytes: e.Size - prev.Size})
+	}
+
+	sort.Slice(grown, func(i, j int) bool { return grown[i].DeltaBytes > grown[j].DeltaBytes })
+	if len(grown) > *topN {
+		grown = grown[:*topN]
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, g := range grown {
+		_ = enc.Encode(g)
+	}
+}
+
+// openCache opens the cache file at path, or cache.DefaultPath() if path
+// is empty.
+func openCache(path string) (*cache.Store, error) {
+	if path == "" {
+		var err error
+		path, err = cache.DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cache.Open(path)
+}
+
+// startProgress runs printProgress in the background and returns a func
+// that blocks until it has drained progress and printed the final
+// snapshot. Callers must invoke the returned func before the process
+// exits (e.g. via defer startProgress(progress)()), or the final
+// snapshot — including the capped error summary — races with exit and
+// can be lost.
+func startProgress(progress <-chan scan.Progress) func() {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		printProgress(progress)
+	}()
+	return func() { <-done }
+}
+
+// printProgress renders a periodic one-line status to stderr while a scan
+// runs, and a trailing newline once the scan's Progress channel closes.
+func printProgress(progress <-chan scan.Progress) {
+	var last scan.Progress
+	for p := range progress {
+		last = p
+		fmt.Fprintf(os.Stderr, "\rscanned %d files, %s seen, %d errors, in %s",
+			p.FilesScanned, units.Human(p.BytesSeen), p.ErrorCount, p.CurrentDir)
+	}
+	if last.ErrorCount > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d errors during scan (showing up to %d):\n", last.ErrorCount, len(last.Errors))
+		for _, e := range last.Errors {
+			fmt.Fprintf(os.Stderr, "  %s\n", e)
+		}
+		return
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
 // splitGlobs splits a comma-separated list of globs into a slice.
 func splitGlobs(s string) []string {
 	if s == "" {